@@ -0,0 +1,132 @@
+package mailgun
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// DoubleOptInConfig describes the confirmation e-mail sent by DoubleOptIn.
+type DoubleOptInConfig struct {
+	// Template is the name of the Mailgun template used to deliver the
+	// confirmation e-mail.
+	Template string
+	// From is the From: address the confirmation e-mail is sent from.
+	From string
+	// Subject is the confirmation e-mail's subject line.
+	Subject string
+	// ConfirmURL builds the link a prospect should click to confirm,
+	// given their address and token. The result is passed to Template as
+	// the "confirm_url" template variable.
+	ConfirmURL func(address, token string) string
+}
+
+// confirmTokenVar is the Vars key DoubleOptIn and ConfirmSubscriber use to
+// store and validate the confirmation token.
+const confirmTokenVar = "confirm_token"
+
+// DoubleOptIn begins a double opt-in subscription for prospect on
+// listAddr: it stores prospect with Subscribed set to false and a freshly
+// generated confirmation token, then sends the confirmation e-mail
+// described by cfg. The subscriber is not marked Subscribed until
+// ConfirmSubscriber validates the token returned to cfg.ConfirmURL.
+// Calling DoubleOptIn again for the same address - e.g. to resend a
+// confirmation e-mail - upserts the prospect with a fresh token rather
+// than failing with a duplicate-member error.
+func (mg *mailgunImpl) DoubleOptIn(listAddr string, prospect Subscriber, cfg DoubleOptInConfig) error {
+	return mg.DoubleOptInWithContext(context.Background(), listAddr, prospect, cfg)
+}
+
+// DoubleOptInWithContext is the same as DoubleOptIn, but allows the
+// caller to pass a context.Context to set a timeout or cancel the
+// subscriber request. The confirmation e-mail is sent via Send, which
+// does not take ctx.
+func (mg *mailgunImpl) DoubleOptInWithContext(ctx context.Context, listAddr string, prospect Subscriber, cfg DoubleOptInConfig) error {
+	token, err := newConfirmToken()
+	if err != nil {
+		return err
+	}
+
+	if prospect.Vars == nil {
+		prospect.Vars = make(map[string]interface{})
+	}
+	prospect.Vars[confirmTokenVar] = token
+	prospect.Subscribed = FilterUnsubscribed
+
+	if err := mg.CreateSubscriberWithContext(ctx, true, listAddr, prospect); err != nil {
+		return err
+	}
+
+	m := mg.NewMessage(cfg.From, cfg.Subject, "", prospect.Address)
+	m.SetTemplate(cfg.Template)
+	m.AddVariable("confirm_url", cfg.ConfirmURL(prospect.Address, token))
+	_, _, err = mg.Send(m)
+	return err
+}
+
+// ConfirmSubscriber validates token against the confirmation token stored
+// for email on listAddr and, if it matches, marks the member Subscribed.
+func (mg *mailgunImpl) ConfirmSubscriber(listAddr, email, token string) error {
+	return mg.ConfirmSubscriberWithContext(context.Background(), listAddr, email, token)
+}
+
+// ConfirmSubscriberWithContext is the same as ConfirmSubscriber, but
+// allows the caller to pass a context.Context to set a timeout or cancel
+// the underlying requests.
+func (mg *mailgunImpl) ConfirmSubscriberWithContext(ctx context.Context, listAddr, email, token string) error {
+	sub, err := mg.GetSubscriberByAddressWithContext(ctx, email, listAddr)
+	if err != nil {
+		return err
+	}
+	want, _ := sub.Vars[confirmTokenVar].(string)
+	if !tokensMatch(want, token) {
+		return fmt.Errorf("mailgun: invalid or expired confirmation token for %s", email)
+	}
+	_, err = mg.UpdateSubscriberWithContext(ctx, email, listAddr, Subscriber{Subscribed: FilterSubscribed})
+	return err
+}
+
+// tokensMatch reports whether token matches the confirmation token want,
+// in constant time and rejecting an empty want (meaning no token was ever
+// stored) outright rather than comparing against it.
+func tokensMatch(want, token string) bool {
+	return want != "" && subtle.ConstantTimeCompare([]byte(want), []byte(token)) == 1
+}
+
+// ConfirmationHandler returns an http.Handler that serves confirmation
+// links at exactly pattern, of the form pattern+"?list=...&email=...&token=..."
+// by calling ConfirmSubscriber; a request to any other path is rejected
+// with 404. Applications can mount it directly:
+//
+//	http.Handle("/confirm", mg.ConfirmationHandler("/confirm"))
+func (mg *mailgunImpl) ConfirmationHandler(pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != pattern {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		listAddr, email, token := q.Get("list"), q.Get("email"), q.Get("token")
+		if listAddr == "" || email == "" || token == "" {
+			http.Error(w, "missing list, email or token parameter", http.StatusBadRequest)
+			return
+		}
+		if err := mg.ConfirmSubscriberWithContext(r.Context(), listAddr, email, token); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%s is now confirmed", email)
+	})
+}
+
+// newConfirmToken returns a random, URL-safe confirmation token.
+func newConfirmToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,47 @@
+package mailgun
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokensMatch(t *testing.T) {
+	if tokensMatch("", "") {
+		t.Error("an empty want must never match, even an empty token")
+	}
+	if tokensMatch("", "abc") {
+		t.Error("an empty want must never match")
+	}
+	if !tokensMatch("abc", "abc") {
+		t.Error("equal, non-empty tokens must match")
+	}
+	if tokensMatch("abc", "abd") {
+		t.Error("differing tokens must not match")
+	}
+}
+
+func TestConfirmationHandlerRejectsWrongPath(t *testing.T) {
+	mg := &mailgunImpl{}
+	h := mg.ConfirmationHandler("/confirm")
+
+	req := httptest.NewRequest("GET", "/not-confirm?list=l@example.com&email=e@example.com&token=t", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != 404 {
+		t.Fatalf("status = %d, want 404 for a path other than the registered pattern", w.Code)
+	}
+}
+
+func TestConfirmationHandlerRequiresParams(t *testing.T) {
+	mg := &mailgunImpl{}
+	h := mg.ConfirmationHandler("/confirm")
+
+	req := httptest.NewRequest("GET", "/confirm?list=l@example.com&email=e@example.com", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("status = %d, want 400 when token is missing", w.Code)
+	}
+}
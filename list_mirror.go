@@ -0,0 +1,230 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// MemberStore is a pluggable local persistence layer for mailing list
+// membership. A ListMirror uses a MemberStore to keep a durable local copy
+// of a list's subscribers, so that an application's view of its
+// membership survives restarts and outlives any single Sync.
+// BoltMemberStore is a ready-to-use reference implementation backed by
+// bbolt; any storage engine that can satisfy these three methods will do.
+type MemberStore interface {
+	// All returns every member currently stored for listAddr.
+	All(listAddr string) ([]Subscriber, error)
+	// Put creates or overwrites the stored copy of member.
+	Put(listAddr string, member Subscriber) error
+	// Delete removes the stored copy of the member at address, if any.
+	Delete(listAddr, address string) error
+}
+
+// MemberEventType identifies the kind of change a Sync observed between
+// the local store and Mailgun.
+type MemberEventType int
+
+const (
+	// MemberAdded indicates a member exists on Mailgun but was missing
+	// from the local store.
+	MemberAdded MemberEventType = iota
+	// MemberRemoved indicates a member existed in the local store but is
+	// no longer present on Mailgun.
+	MemberRemoved
+	// MemberChanged indicates a member exists in both places, but its
+	// Name, Subscribed, or Vars differ.
+	MemberChanged
+)
+
+// String returns a human-readable name for the event type.
+func (t MemberEventType) String() string {
+	switch t {
+	case MemberAdded:
+		return "added"
+	case MemberRemoved:
+		return "removed"
+	case MemberChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// MemberEvent describes one difference found between the local store and
+// Mailgun's copy of a list's membership during a Sync.
+type MemberEvent struct {
+	Type MemberEventType
+	// Member is Mailgun's current copy for Added and Changed events, and
+	// the last known local copy for Removed events.
+	Member Subscriber
+	// VarsDiff is set only for Changed events, and maps each Vars key
+	// whose value differs to Mailgun's value for that key (nil if the key
+	// was removed on Mailgun's side).
+	VarsDiff map[string]interface{}
+}
+
+// ListMirror keeps a MemberStore in sync with a single Mailgun mailing
+// list, so that an application backed by its own database can treat
+// Mailgun as an eventually-consistent replica of its subscriber table
+// rather than as the system of record.
+type ListMirror struct {
+	mg     *mailgunImpl
+	store  MemberStore
+	addr   string
+	events chan<- MemberEvent
+}
+
+// NewListMirror creates a ListMirror for the mailing list at addr, backed
+// by store. If events is non-nil, Sync sends a MemberEvent to it for
+// every difference it finds; events must be drained promptly; it is
+// never closed by ListMirror.
+func (mg *mailgunImpl) NewListMirror(addr string, store MemberStore, events chan<- MemberEvent) *ListMirror {
+	return &ListMirror{mg: mg, store: store, addr: addr, events: events}
+}
+
+// Sync pages through every subscriber of the mirrored list, reconciling
+// Mailgun's copy against the local store: members present only on
+// Mailgun are stored locally and reported as MemberAdded, members present
+// only locally are deleted and reported as MemberRemoved, and members
+// whose Name, Subscribed, or Vars differ are updated locally and reported
+// as MemberChanged.
+func (lm *ListMirror) Sync(ctx context.Context) error {
+	remote := make(map[string]Subscriber)
+	it := lm.mg.SubscribersIter(lm.addr, FilterAll)
+	for it.Next(ctx) {
+		s := it.Item()
+		remote[s.Address] = s
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	local, err := lm.store.All(lm.addr)
+	if err != nil {
+		return err
+	}
+	localByAddr := make(map[string]Subscriber, len(local))
+	for _, s := range local {
+		localByAddr[s.Address] = s
+	}
+
+	for addr, remoteMember := range remote {
+		localMember, ok := localByAddr[addr]
+		if !ok {
+			if err := lm.store.Put(lm.addr, remoteMember); err != nil {
+				return err
+			}
+			lm.emit(ctx, MemberEvent{Type: MemberAdded, Member: remoteMember})
+			continue
+		}
+		if changed, diff := memberChanged(localMember, remoteMember); changed {
+			if err := lm.store.Put(lm.addr, remoteMember); err != nil {
+				return err
+			}
+			lm.emit(ctx, MemberEvent{Type: MemberChanged, Member: remoteMember, VarsDiff: diff})
+		}
+	}
+
+	for addr, localMember := range localByAddr {
+		if _, ok := remote[addr]; !ok {
+			if err := lm.store.Delete(lm.addr, addr); err != nil {
+				return err
+			}
+			lm.emit(ctx, MemberEvent{Type: MemberRemoved, Member: localMember})
+		}
+	}
+
+	return nil
+}
+
+// maxPushAttempts bounds the attempts, first try included, Subscribe and
+// Unsubscribe make against Mailgun once the local store has already been
+// updated.
+const maxPushAttempts = 3
+
+// Subscribe writes a member to the local store first, then pushes the
+// change to Mailgun via CreateSubscriber (with merge enabled), retrying
+// on any error for up to maxPushAttempts attempts in all. The local store is
+// therefore never behind what the caller asked for, even if Mailgun is
+// briefly unreachable.
+func (lm *ListMirror) Subscribe(ctx context.Context, email, name string, vars map[string]interface{}) error {
+	member := Subscriber{Address: email, Name: name, Subscribed: FilterSubscribed, Vars: vars}
+	if err := lm.store.Put(lm.addr, member); err != nil {
+		return err
+	}
+	return lm.push(func() error {
+		return lm.mg.CreateSubscriberWithContext(ctx, true, lm.addr, member)
+	})
+}
+
+// Unsubscribe removes a member from the local store first, then marks the
+// member unsubscribed on Mailgun via UpdateSubscriber, retrying on any
+// error for up to maxPushAttempts attempts in all. The member is not
+// removed from the list on Mailgun's side.
+func (lm *ListMirror) Unsubscribe(ctx context.Context, email string) error {
+	if err := lm.store.Delete(lm.addr, email); err != nil {
+		return err
+	}
+	return lm.push(func() error {
+		_, err := lm.mg.UpdateSubscriberWithContext(ctx, email, lm.addr, Subscriber{Subscribed: FilterUnsubscribed})
+		return err
+	})
+}
+
+func (lm *ListMirror) push(f func() error) error {
+	var err error
+	for attempt := 0; attempt < maxPushAttempts; attempt++ {
+		if err = f(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("mailgun: giving up after %d attempts: %s", maxPushAttempts, err)
+}
+
+// emit delivers ev to lm.events, but gives up as soon as ctx is done
+// instead of blocking forever on a consumer that never drains the
+// channel.
+func (lm *ListMirror) emit(ctx context.Context, ev MemberEvent) {
+	if lm.events == nil {
+		return
+	}
+	select {
+	case lm.events <- ev:
+	case <-ctx.Done():
+	}
+}
+
+// memberChanged reports whether remote differs from local in any field
+// Sync cares about - Name, Subscribed, or Vars - along with the Vars diff,
+// if any.
+func memberChanged(local, remote Subscriber) (bool, map[string]interface{}) {
+	diff := varsDiff(local.Vars, remote.Vars)
+	changed := len(diff) > 0 || local.Name != remote.Name || local.Subscribed != remote.Subscribed
+	return changed, diff
+}
+
+// varsDiff returns the keys on which local and remote differ - including
+// keys present in only one of the two maps - mapped to remote's value
+// (nil for a key that was removed on Mailgun's side).
+func varsDiff(local, remote map[string]interface{}) map[string]interface{} {
+	var diff map[string]interface{}
+	for k := range local {
+		if _, ok := remote[k]; ok {
+			continue
+		}
+		if diff == nil {
+			diff = make(map[string]interface{})
+		}
+		diff[k] = nil
+	}
+	for k, v := range remote {
+		if !reflect.DeepEqual(local[k], v) {
+			if diff == nil {
+				diff = make(map[string]interface{})
+			}
+			diff[k] = v
+		}
+	}
+	return diff
+}
@@ -0,0 +1,79 @@
+package mailgun
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltMemberStore is a MemberStore backed by a bbolt database, letting an
+// application mirror a mailing list to disk without running a separate
+// database server. Each mailing list gets its own bucket, keyed by member
+// address, holding the JSON-encoded Subscriber as the value.
+type BoltMemberStore struct {
+	db *bolt.DB
+}
+
+// NewBoltMemberStore opens (creating if necessary) a bbolt database at
+// path and returns a MemberStore backed by it. The caller is responsible
+// for calling Close once done with it.
+func NewBoltMemberStore(path string) (*BoltMemberStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BoltMemberStore{db: db}, nil
+}
+
+// Close releases the underlying bbolt database.
+func (s *BoltMemberStore) Close() error {
+	return s.db.Close()
+}
+
+// All returns every member currently stored for listAddr.
+func (s *BoltMemberStore) All(listAddr string) ([]Subscriber, error) {
+	var members []Subscriber
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(listAddr))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var m Subscriber
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+			members = append(members, m)
+			return nil
+		})
+	})
+	return members, err
+}
+
+// Put creates or overwrites the stored copy of member.
+func (s *BoltMemberStore) Put(listAddr string, member Subscriber) error {
+	v, err := json.Marshal(member)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(listAddr))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(member.Address), v)
+	})
+}
+
+// Delete removes the stored copy of the member at address, if any.
+func (s *BoltMemberStore) Delete(listAddr, address string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(listAddr))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(address))
+	})
+}
+
+var _ MemberStore = (*BoltMemberStore)(nil)
@@ -0,0 +1,84 @@
+package mailgun
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVarsDiff(t *testing.T) {
+	cases := []struct {
+		name   string
+		local  map[string]interface{}
+		remote map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{"no change", map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}, nil},
+		{"value changed", map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 2.0}, map[string]interface{}{"a": 2.0}},
+		{"key added remotely", map[string]interface{}{}, map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}},
+		{"key removed remotely", map[string]interface{}{"a": 1.0}, map[string]interface{}{}, map[string]interface{}{"a": nil}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := varsDiff(c.local, c.remote)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("varsDiff(%v, %v) = %v, want %v", c.local, c.remote, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMemberChanged(t *testing.T) {
+	local := Subscriber{Name: "A", Subscribed: FilterSubscribed, Vars: map[string]interface{}{"k": "v"}}
+
+	if changed, _ := memberChanged(local, local); changed {
+		t.Fatal("identical members should not be reported as changed")
+	}
+
+	remote := local
+	remote.Subscribed = FilterUnsubscribed
+	if changed, _ := memberChanged(local, remote); !changed {
+		t.Fatal("a Subscribed-only change must be detected")
+	}
+
+	remote = local
+	remote.Vars = map[string]interface{}{}
+	if changed, diff := memberChanged(local, remote); !changed {
+		t.Fatalf("a removed Vars key must be detected, got changed=%v diff=%v", changed, diff)
+	} else if v, ok := diff["k"]; !ok || v != nil {
+		t.Fatalf("expected diff to report removed key k as nil, got %v", diff)
+	}
+}
+
+func TestListMirrorPushRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	lm := &ListMirror{}
+	err := lm.push(func() error {
+		attempts++
+		if attempts < maxPushAttempts {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("push should have succeeded on the final attempt: %v", err)
+	}
+	if attempts != maxPushAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxPushAttempts, attempts)
+	}
+}
+
+func TestListMirrorPushGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	lm := &ListMirror{}
+	err := lm.push(func() error {
+		attempts++
+		return errors.New("persistent")
+	})
+	if err == nil {
+		t.Fatal("push should report an error once attempts are exhausted")
+	}
+	if attempts != maxPushAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxPushAttempts, attempts)
+	}
+}
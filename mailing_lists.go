@@ -1,90 +1,201 @@
 package mailgun
 
 import (
-	"github.com/mbanzon/simplehttp"
-	"strconv"
+	"context"
 	"encoding/json"
-//	"fmt"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 )
 
-// A mailing list may have one of three membership modes.
-// ReadOnly specifies that nobody, including subscribers,
-// may send messages to the mailing list.
-// Messages distributed on such lists come from list administrator accounts only.
-// Members specifies that only those who subscribe to the mailing list may send messages.
-// Everyone specifies that anyone and everyone may both read and submit messages
-// to the mailing list, including non-subscribers.
+// AccessLevel controls who may read and post to a mailing list.
+type AccessLevel string
+
 const (
-	ReadOnly = "readonly"
-	Members = "members"
-	Everyone = "everyone"
+	// AccessReadOnly specifies that nobody, including subscribers, may
+	// send messages to the mailing list. Messages distributed on such
+	// lists come from list administrator accounts only.
+	AccessReadOnly AccessLevel = "readonly"
+	// AccessMembers specifies that only those who subscribe to the
+	// mailing list may send messages.
+	AccessMembers AccessLevel = "members"
+	// AccessEveryone specifies that anyone and everyone may both read and
+	// submit messages to the mailing list, including non-subscribers.
+	AccessEveryone AccessLevel = "everyone"
 )
 
-// Mailing list members have an attribute that determines if they've subscribed to the mailing list or not.
-// This attribute may be used to filter the results returned by GetSubscribers().
-// All, Subscribed, and Unsubscribed provides a convenient and readable syntax for specifying the scope of the search.
-var (
-	All *bool = nil
-	Subscribed *bool = &yes
-	Unsubscribed *bool = &no
-)
+// Deprecated: use AccessReadOnly instead.
+const ReadOnly = AccessReadOnly
 
-// yes and no are variables which provide us the ability to take their addresses.
-// Subscribed and Unsubscribed are pointers to these booleans.
-//
-// We use a pointer to boolean as a kind of trinary data type:
-// if nil, the relevant data type remains unspecified.
-// Otherwise, its value is either true or false.
-var (
-	yes bool = true
-	no bool = false
+// Deprecated: use AccessMembers instead.
+const Members = AccessMembers
+
+// Deprecated: use AccessEveryone instead.
+const Everyone = AccessEveryone
+
+// String returns the wire representation of the access level.
+func (a AccessLevel) String() string {
+	return string(a)
+}
+
+// Valid reports whether a is the zero value (unspecified) or one of the
+// known access levels.
+func (a AccessLevel) Valid() bool {
+	switch a {
+	case "", AccessReadOnly, AccessMembers, AccessEveryone:
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a AccessLevel) MarshalJSON() ([]byte, error) {
+	if !a.Valid() {
+		return nil, fmt.Errorf("mailgun: invalid access level %q", string(a))
+	}
+	return json.Marshal(string(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *AccessLevel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v := AccessLevel(s)
+	if !v.Valid() {
+		return fmt.Errorf("mailgun: invalid access level %q", s)
+	}
+	*a = v
+	return nil
+}
+
+// SubscriptionFilter narrows the members returned by GetSubscribers, and
+// states the desired subscription status of a Subscriber passed to
+// CreateSubscriber or UpdateSubscriber.
+type SubscriptionFilter int
+
+const (
+	// FilterAll matches subscribed and unsubscribed members alike. As the
+	// zero value, it is also what a freshly constructed Subscriber carries
+	// when its desired subscription status is left unspecified; in that
+	// position it tells Mailgun to fall back to its own default.
+	FilterAll SubscriptionFilter = iota
+	// FilterSubscribed matches, or requests, subscribed members only.
+	FilterSubscribed
+	// FilterUnsubscribed matches, or requests, unsubscribed members only.
+	FilterUnsubscribed
 )
 
+// Deprecated: use FilterAll instead. Note this is a breaking rename, not
+// a source-compatible shim: All used to be a *bool and is now a
+// SubscriptionFilter, so code that passed a *bool of its own (rather than
+// this named sentinel) to GetSubscribers or Subscriber.Subscribed will
+// need to be migrated to SubscriptionFilter; only call sites already
+// using the All/Subscribed/Unsubscribed names keep compiling unchanged.
+const All = FilterAll
+
+// Deprecated: use FilterSubscribed instead. See the note on All.
+const Subscribed = FilterSubscribed
+
+// Deprecated: use FilterUnsubscribed instead. See the note on All.
+const Unsubscribed = FilterUnsubscribed
+
+// String returns the wire representation this package uses for the
+// "subscribed" form field, or "" for FilterAll.
+func (f SubscriptionFilter) String() string {
+	switch f {
+	case FilterSubscribed:
+		return "yes"
+	case FilterUnsubscribed:
+		return "no"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON implements json.Marshaler. It encodes to the true/false/null
+// that Mailgun's own "subscribed" JSON field uses, matching the format
+// SubscriptionFilter is actually unmarshaled from in API responses.
+func (f SubscriptionFilter) MarshalJSON() ([]byte, error) {
+	switch f {
+	case FilterSubscribed:
+		return json.Marshal(true)
+	case FilterUnsubscribed:
+		return json.Marshal(false)
+	default:
+		return json.Marshal(nil)
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the true/false/null
+// that Mailgun sends for a member's "subscribed" field.
+func (f *SubscriptionFilter) UnmarshalJSON(data []byte) error {
+	var b *bool
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	switch {
+	case b == nil:
+		*f = FilterAll
+	case *b:
+		*f = FilterSubscribed
+	default:
+		*f = FilterUnsubscribed
+	}
+	return nil
+}
+
 // A List structure provides information for a mailing list.
 //
-// AccessLevel may be one of ReadOnly, Members, or Everyone.
+// AccessLevel may be one of AccessReadOnly, AccessMembers, or AccessEveryone.
 type List struct {
-	Address      string `json:"address",omitempty"`
-	Name         string `json:"name",omitempty"`
-	Description  string `json:"description",omitempty"`
-	AccessLevel  string `json:"access_level",omitempty"`
-	CreatedAt    string `json:"created_at",omitempty"`
-	MembersCount int    `json:"members_count",omitempty"`
+	Address      string      `json:"address,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	Description  string      `json:"description,omitempty"`
+	AccessLevel  AccessLevel `json:"access_level,omitempty"`
+	CreatedAt    string      `json:"created_at,omitempty"`
+	MembersCount int         `json:"members_count,omitempty"`
 }
 
 // A Subscriber structure represents a member of the mailing list.
 // The Vars field can represent any JSON-encodable data.
 type Subscriber struct {
-	Address    string `json:"address,omitempty"`
-	Name       string `json:"name,omitempty"`
-	Subscribed *bool `json:"subscribed,omitempty"`
+	Address    string                 `json:"address,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Subscribed SubscriptionFilter     `json:"subscribed,omitempty"`
 	Vars       map[string]interface{} `json:"vars,omitempty"`
 }
 
 // GetLists returns the specified set of mailing lists administered by your account.
 func (mg *mailgunImpl) GetLists(limit, skip int, filter string) (int, []List, error) {
-	r := simplehttp.NewHTTPRequest(generatePublicApiUrl(listsEndpoint))
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewUrlEncodedPayload()
+	return mg.GetListsWithContext(context.Background(), limit, skip, filter)
+}
+
+// GetListsWithContext is the same as GetLists, but allows the caller to
+// pass a context.Context to set a timeout or cancel the request.
+func (mg *mailgunImpl) GetListsWithContext(ctx context.Context, limit, skip int, filter string) (int, []List, error) {
+	p := url.Values{}
 	if limit != DefaultLimit {
-		p.AddValue("limit", strconv.Itoa(limit))
+		p.Set("limit", strconv.Itoa(limit))
 	}
 	if skip != DefaultSkip {
-		p.AddValue("skip", strconv.Itoa(skip))
+		p.Set("skip", strconv.Itoa(skip))
 	}
 	if filter != "" {
-		p.AddValue("address", filter)
+		p.Set("address", filter)
 	}
 	var envelope struct {
-		Items []List `json:"items"`
-		TotalCount int `json:"total_count"`
+		Items      []List `json:"items"`
+		TotalCount int    `json:"total_count"`
 	}
-	response, err := r.MakeRequest("GET", p)
+	err := mg.callContext(ctx, http.MethodGet, generatePublicApiUrl(listsEndpoint), p, &envelope)
 	if err != nil {
 		return -1, nil, err
 	}
-	err = response.ParseFromJSON(&envelope)
-	return envelope.TotalCount, envelope.Items, err
+	return envelope.TotalCount, envelope.Items, nil
 }
 
 // CreateList creates a new mailing list under your Mailgun account.
@@ -93,49 +204,60 @@ func (mg *mailgunImpl) GetLists(limit, skip int, filter string) (int, []List, er
 // If unspecified, Description remains blank,
 // while AccessLevel defaults to Everyone.
 func (mg *mailgunImpl) CreateList(prototype List) (List, error) {
-	r := simplehttp.NewHTTPRequest(generatePublicApiUrl(listsEndpoint))
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewUrlEncodedPayload()
+	return mg.CreateListWithContext(context.Background(), prototype)
+}
+
+// CreateListWithContext is the same as CreateList, but allows the caller
+// to pass a context.Context to set a timeout or cancel the request.
+func (mg *mailgunImpl) CreateListWithContext(ctx context.Context, prototype List) (List, error) {
+	if !prototype.AccessLevel.Valid() {
+		return List{}, fmt.Errorf("mailgun: invalid access level %q", prototype.AccessLevel)
+	}
+
+	p := url.Values{}
 	if prototype.Address != "" {
-		p.AddValue("address", prototype.Address)
+		p.Set("address", prototype.Address)
 	}
 	if prototype.Name != "" {
-		p.AddValue("name", prototype.Name)
+		p.Set("name", prototype.Name)
 	}
 	if prototype.Description != "" {
-		p.AddValue("description", prototype.Description)
+		p.Set("description", prototype.Description)
 	}
 	if prototype.AccessLevel != "" {
-		p.AddValue("access_level", prototype.AccessLevel)
-	}
-	response, err := r.MakePostRequest(p)
-	if err != nil {
-		return List{}, err
+		p.Set("access_level", prototype.AccessLevel.String())
 	}
 	var l List
-	err = response.ParseFromJSON(&l)
+	err := mg.callContext(ctx, http.MethodPost, generatePublicApiUrl(listsEndpoint), p, &l)
 	return l, err
 }
 
 // DeleteList removes all current members of the list, then removes the list itself.
 // Attempts to send e-mail to the list will fail subsequent to this call.
 func (mg *mailgunImpl) DeleteList(addr string) error {
-	r := simplehttp.NewHTTPRequest(generatePublicApiUrl(listsEndpoint) + "/" + addr)
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	_, err := r.MakeDeleteRequest()
-	return err
+	return mg.DeleteListWithContext(context.Background(), addr)
+}
+
+// DeleteListWithContext is the same as DeleteList, but allows the caller
+// to pass a context.Context to set a timeout or cancel the request.
+func (mg *mailgunImpl) DeleteListWithContext(ctx context.Context, addr string) error {
+	return mg.callContext(ctx, http.MethodDelete, generatePublicApiUrl(listsEndpoint)+"/"+addr, nil, nil)
 }
 
 // GetListByAddress allows your application to recover the complete List structure
 // representing a mailing list, so long as you have its e-mail address.
 func (mg *mailgunImpl) GetListByAddress(addr string) (List, error) {
-	r := simplehttp.NewHTTPRequest(generatePublicApiUrl(listsEndpoint) + "/" + addr)
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	response, err := r.MakeGetRequest()
+	return mg.GetListByAddressWithContext(context.Background(), addr)
+}
+
+// GetListByAddressWithContext is the same as GetListByAddress, but allows
+// the caller to pass a context.Context to set a timeout or cancel the
+// request.
+func (mg *mailgunImpl) GetListByAddressWithContext(ctx context.Context, addr string) (List, error) {
 	var envelope struct {
 		List `json:"list"`
 	}
-	err = response.ParseFromJSON(&envelope)
+	err := mg.callContext(ctx, http.MethodGet, generatePublicApiUrl(listsEndpoint)+"/"+addr, nil, &envelope)
 	return envelope.List, err
 }
 
@@ -147,72 +269,86 @@ func (mg *mailgunImpl) GetListByAddress(addr string) (List, error) {
 // e-mail sent to the old address will not succeed.
 // Make sure you account for the change accordingly.
 func (mg *mailgunImpl) UpdateList(addr string, prototype List) (List, error) {
-	r := simplehttp.NewHTTPRequest(generatePublicApiUrl(listsEndpoint) + "/" + addr)
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewUrlEncodedPayload()
+	return mg.UpdateListWithContext(context.Background(), addr, prototype)
+}
+
+// UpdateListWithContext is the same as UpdateList, but allows the caller
+// to pass a context.Context to set a timeout or cancel the request.
+func (mg *mailgunImpl) UpdateListWithContext(ctx context.Context, addr string, prototype List) (List, error) {
+	if !prototype.AccessLevel.Valid() {
+		return List{}, fmt.Errorf("mailgun: invalid access level %q", prototype.AccessLevel)
+	}
+
+	p := url.Values{}
 	if prototype.Address != "" {
-		p.AddValue("address", prototype.Address)
+		p.Set("address", prototype.Address)
 	}
 	if prototype.Name != "" {
-		p.AddValue("name", prototype.Name)
+		p.Set("name", prototype.Name)
 	}
 	if prototype.Description != "" {
-		p.AddValue("description", prototype.Description)
+		p.Set("description", prototype.Description)
 	}
 	if prototype.AccessLevel != "" {
-		p.AddValue("access_level", prototype.AccessLevel)
+		p.Set("access_level", prototype.AccessLevel.String())
 	}
 	var l List
-	response, err := r.MakePutRequest(p)
-	if err != nil {
-		return l, err
-	}
-	err = response.ParseFromJSON(&l)
+	err := mg.callContext(ctx, http.MethodPut, generatePublicApiUrl(listsEndpoint)+"/"+addr, p, &l)
 	return l, err
 }
 
 // GetSubscribers returns the list of members belonging to the indicated mailing list.
-// The s parameter can be set to one of three settings to help narrow the returned data set:
-// All indicates that you want both subscribers and unsubscribed members alike, while
-// Subscribed and Unsubscribed indicate you want only those eponymous subsets.
-func (mg *mailgunImpl) GetSubscribers(limit, skip int, s *bool, addr string) (int, []Subscriber, error) {
-	r := simplehttp.NewHTTPRequest(generateSubscriberApiUrl(listsEndpoint, addr))
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewUrlEncodedPayload()
+// The filter parameter narrows the returned data set: FilterAll indicates
+// that you want both subscribed and unsubscribed members alike, while
+// FilterSubscribed and FilterUnsubscribed indicate you want only those
+// eponymous subsets.
+//
+// filter used to be a *bool; that signature is gone, not deprecated, so
+// callers passing their own *bool (rather than the All/Subscribed/
+// Unsubscribed sentinels, which still compile) need to migrate to
+// SubscriptionFilter to build against this version.
+func (mg *mailgunImpl) GetSubscribers(limit, skip int, filter SubscriptionFilter, addr string) (int, []Subscriber, error) {
+	return mg.GetSubscribersWithContext(context.Background(), limit, skip, filter, addr)
+}
+
+// GetSubscribersWithContext is the same as GetSubscribers, but allows the
+// caller to pass a context.Context to set a timeout or cancel the request.
+func (mg *mailgunImpl) GetSubscribersWithContext(ctx context.Context, limit, skip int, filter SubscriptionFilter, addr string) (int, []Subscriber, error) {
+	p := url.Values{}
 	if limit != DefaultLimit {
-		p.AddValue("limit", strconv.Itoa(limit))
+		p.Set("limit", strconv.Itoa(limit))
 	}
 	if skip != DefaultSkip {
-		p.AddValue("skip", strconv.Itoa(skip))
+		p.Set("skip", strconv.Itoa(skip))
 	}
-	if s != nil {
-		p.AddValue("subscribed", yesNo(*s))
+	if filter != FilterAll {
+		p.Set("subscribed", filter.String())
 	}
 	var envelope struct {
-		TotalCount int `json:"total_count"`
-		Items []Subscriber `json:"items"`
+		TotalCount int          `json:"total_count"`
+		Items      []Subscriber `json:"items"`
 	}
-	response, err := r.MakeRequest("GET", p)
+	err := mg.callContext(ctx, http.MethodGet, generateSubscriberApiUrl(listsEndpoint, addr), p, &envelope)
 	if err != nil {
 		return -1, nil, err
 	}
-	err = response.ParseFromJSON(&envelope)
-	return envelope.TotalCount, envelope.Items, err
+	return envelope.TotalCount, envelope.Items, nil
 }
 
 // GetSubscriberByAddress returns a complete Subscriber structure for a member of a mailing list,
 // given only their subscription e-mail address.
 func (mg *mailgunImpl) GetSubscriberByAddress(s, l string) (Subscriber, error) {
-	r := simplehttp.NewHTTPRequest(generateSubscriberApiUrl(listsEndpoint, l) + "/" + s)
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	response, err := r.MakeGetRequest()
-	if err != nil {
-		return Subscriber{}, err
-	}
+	return mg.GetSubscriberByAddressWithContext(context.Background(), s, l)
+}
+
+// GetSubscriberByAddressWithContext is the same as GetSubscriberByAddress,
+// but allows the caller to pass a context.Context to set a timeout or
+// cancel the request.
+func (mg *mailgunImpl) GetSubscriberByAddressWithContext(ctx context.Context, s, l string) (Subscriber, error) {
 	var envelope struct {
 		Member Subscriber `json:"member"`
 	}
-	err = response.ParseFromJSON(&envelope)
+	err := mg.callContext(ctx, http.MethodGet, generateSubscriberApiUrl(listsEndpoint, l)+"/"+s, nil, &envelope)
 	return envelope.Member, err
 }
 
@@ -220,54 +356,119 @@ func (mg *mailgunImpl) GetSubscriberByAddress(s, l string) (Subscriber, error) {
 // If merge is set to true, then the registration may update an existing subscriber's settings.
 // Otherwise, an error will occur if you attempt to add a member with a duplicate e-mail address.
 func (mg *mailgunImpl) CreateSubscriber(merge bool, addr string, prototype Subscriber) error {
+	return mg.CreateSubscriberWithContext(context.Background(), merge, addr, prototype)
+}
+
+// CreateSubscriberWithContext is the same as CreateSubscriber, but allows
+// the caller to pass a context.Context to set a timeout or cancel the
+// request.
+func (mg *mailgunImpl) CreateSubscriberWithContext(ctx context.Context, merge bool, addr string, prototype Subscriber) error {
 	vs, err := json.Marshal(prototype.Vars)
 	if err != nil {
 		return err
 	}
 
-	r := simplehttp.NewHTTPRequest(generateSubscriberApiUrl(listsEndpoint, addr))
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewFormDataPayload()
-	p.AddValue("upsert", yesNo(merge))
-	p.AddValue("address", prototype.Address)
-	p.AddValue("name", prototype.Name)
-	p.AddValue("vars", string(vs))
-	if prototype.Subscribed != nil {
-		p.AddValue("subscribed", yesNo(*prototype.Subscribed))
+	p := url.Values{}
+	p.Set("upsert", yesNo(merge))
+	p.Set("address", prototype.Address)
+	p.Set("name", prototype.Name)
+	p.Set("vars", string(vs))
+	if prototype.Subscribed != FilterAll {
+		p.Set("subscribed", prototype.Subscribed.String())
 	}
-	_, err = r.MakePostRequest(p)
-	return err
+	return mg.callContext(ctx, http.MethodPost, generateSubscriberApiUrl(listsEndpoint, addr), p, nil)
+}
+
+// maxSubscribersPerBatch caps the number of members accepted by Mailgun's
+// bulk /lists/{addr}/members.json endpoint in a single request.
+const maxSubscribersPerBatch = 1000
+
+// CreateSubscriberList registers many new members of the indicated mailing
+// list in as few round-trips as possible.
+// Each element of newMembers must be either a string, giving the new member's e-mail address,
+// or a Subscriber, giving their address, name, and vars all at once.
+// If subscribed is not FilterAll, it overrides the subscription status
+// carried by any Subscriber values and applies to every plain e-mail
+// address as well; FilterAll leaves the subscription status up to
+// Mailgun (or to each Subscriber's own Subscribed field).
+// newMembers may be arbitrarily long: it is split into batches of
+// maxSubscribersPerBatch, Mailgun's limit on a single bulk request.
+func (mg *mailgunImpl) CreateSubscriberList(subscribed SubscriptionFilter, addr string, newMembers []interface{}) error {
+	return mg.CreateSubscriberListWithContext(context.Background(), subscribed, addr, newMembers)
+}
+
+// CreateSubscriberListWithContext is the same as CreateSubscriberList, but
+// allows the caller to pass a context.Context to set a timeout or cancel
+// the (possibly multi-request) operation.
+func (mg *mailgunImpl) CreateSubscriberListWithContext(ctx context.Context, subscribed SubscriptionFilter, addr string, newMembers []interface{}) error {
+	for _, r := range batchRanges(len(newMembers), maxSubscribersPerBatch) {
+		if err := mg.createSubscriberListBatch(ctx, subscribed, addr, newMembers[r[0]:r[1]]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchRanges splits n items into consecutive, non-overlapping [start, end)
+// ranges of at most size items each, in order. It returns nil for n <= 0.
+func batchRanges(n, size int) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < n; i += size {
+		end := i + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{i, end})
+	}
+	return ranges
+}
+
+// createSubscriberListBatch performs a single call against the bulk
+// members.json endpoint for up to maxSubscribersPerBatch members.
+func (mg *mailgunImpl) createSubscriberListBatch(ctx context.Context, subscribed SubscriptionFilter, addr string, members []interface{}) error {
+	b, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+
+	p := url.Values{}
+	p.Set("members", string(b))
+	if subscribed != FilterAll {
+		p.Set("subscribed", subscribed.String())
+	}
+	return mg.callContext(ctx, http.MethodPost, generateSubscriberApiUrl(listsEndpoint, addr)+".json", p, nil)
 }
 
 // UpdateSubscriber lets you change certain details about the indicated mailing list member.
 // Address, Name, Vars, and Subscribed fields may be changed.
 func (mg *mailgunImpl) UpdateSubscriber(s, l string, prototype Subscriber) (Subscriber, error) {
-	r := simplehttp.NewHTTPRequest(generateSubscriberApiUrl(listsEndpoint, l) + "/" + s)
-	r.SetBasicAuth(basicAuthUser, mg.ApiKey())
-	p := simplehttp.NewFormDataPayload()
+	return mg.UpdateSubscriberWithContext(context.Background(), s, l, prototype)
+}
+
+// UpdateSubscriberWithContext is the same as UpdateSubscriber, but allows
+// the caller to pass a context.Context to set a timeout or cancel the
+// request.
+func (mg *mailgunImpl) UpdateSubscriberWithContext(ctx context.Context, s, l string, prototype Subscriber) (Subscriber, error) {
+	p := url.Values{}
 	if prototype.Address != "" {
-		p.AddValue("address", prototype.Address)
+		p.Set("address", prototype.Address)
 	}
 	if prototype.Name != "" {
-		p.AddValue("name", prototype.Name)
+		p.Set("name", prototype.Name)
 	}
 	if prototype.Vars != nil {
 		vs, err := json.Marshal(prototype.Vars)
 		if err != nil {
 			return Subscriber{}, err
 		}
-		p.AddValue("vars", string(vs))
+		p.Set("vars", string(vs))
 	}
-	if prototype.Subscribed != nil {
-		p.AddValue("subscribed", yesNo(*prototype.Subscribed))
-	}
-	response, err := r.MakePutRequest(p)
-	if err != nil {
-		return Subscriber{}, err
+	if prototype.Subscribed != FilterAll {
+		p.Set("subscribed", prototype.Subscribed.String())
 	}
 	var envelope struct {
 		Member Subscriber `json:"member"`
 	}
-	err = response.ParseFromJSON(&envelope)
+	err := mg.callContext(ctx, http.MethodPut, generateSubscriberApiUrl(listsEndpoint, l)+"/"+s, p, &envelope)
 	return envelope.Member, err
-}
\ No newline at end of file
+}
@@ -0,0 +1,151 @@
+package mailgun
+
+import "context"
+
+// advancePage folds the page-bookkeeping shared by ListsIterator.Next and
+// SubscribersIterator.Next into a pure, directly testable function: given
+// the skip offset a page was fetched at, the number of items that page
+// returned, and the total item count Mailgun reported, it returns the skip
+// offset for the next page and whether iteration is now done.
+func advancePage(skip, itemCount, total int) (newSkip int, done bool) {
+	newSkip = skip + itemCount
+	done = itemCount == 0 || newSkip >= total
+	return newSkip, done
+}
+
+// ListsIterator walks every mailing list matching a filter, paging through
+// GetLists as needed so callers don't have to manage limit/skip offsets
+// themselves. Obtain one via mailgunImpl.ListsIter.
+type ListsIterator struct {
+	mg     *mailgunImpl
+	filter string
+
+	items []List
+	pos   int
+	skip  int
+	total int
+	err   error
+	done  bool
+}
+
+// ListsIter returns a ListsIterator over every mailing list whose address
+// matches filter. An empty filter matches all lists.
+func (mg *mailgunImpl) ListsIter(filter string) *ListsIterator {
+	return &ListsIterator{mg: mg, filter: filter}
+}
+
+// Next advances the iterator to the next list, fetching another page from
+// Mailgun once the current one is exhausted. It returns false once there
+// are no more lists to visit or an error occurs; callers should check Err
+// to tell the two apart.
+func (it *ListsIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.items) {
+		if it.done {
+			return false
+		}
+		total, items, err := it.mg.GetListsWithContext(ctx, DefaultLimit, it.skip, it.filter)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.total = total
+		it.items = items
+		it.pos = 0
+		it.skip, it.done = advancePage(it.skip, len(items), total)
+		if len(items) == 0 {
+			return false
+		}
+	}
+	it.pos++
+	return true
+}
+
+// Item returns the list the most recent call to Next advanced to.
+func (it *ListsIterator) Item() List {
+	return it.items[it.pos-1]
+}
+
+// Err returns the error, if any, that stopped iteration.
+func (it *ListsIterator) Err() error {
+	return it.err
+}
+
+// Close releases any resources held by the iterator. It is always safe to
+// call, and currently always returns nil; it exists so ListsIterator can
+// later move to cursor-based pagination without changing callers.
+func (it *ListsIterator) Close() error {
+	return nil
+}
+
+// SubscribersIterator walks every member of a mailing list, paging through
+// GetSubscribers as needed. Obtain one via mailgunImpl.SubscribersIter.
+type SubscribersIterator struct {
+	mg     *mailgunImpl
+	addr   string
+	filter SubscriptionFilter
+
+	items []Subscriber
+	pos   int
+	skip  int
+	total int
+	err   error
+	done  bool
+}
+
+// SubscribersIter returns a SubscribersIterator over the members of the
+// mailing list at listAddr. filter narrows the result set exactly as the
+// filter parameter of GetSubscribers does: FilterAll visits every member,
+// while FilterSubscribed or FilterUnsubscribed restrict the iterator to
+// that subset.
+func (mg *mailgunImpl) SubscribersIter(listAddr string, filter SubscriptionFilter) *SubscribersIterator {
+	return &SubscribersIterator{mg: mg, addr: listAddr, filter: filter}
+}
+
+// Next advances the iterator to the next member, fetching another page
+// from Mailgun once the current one is exhausted. It returns false once
+// there are no more members to visit or an error occurs; callers should
+// check Err to tell the two apart.
+func (it *SubscribersIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.items) {
+		if it.done {
+			return false
+		}
+		total, items, err := it.mg.GetSubscribersWithContext(ctx, DefaultLimit, it.skip, it.filter, it.addr)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.total = total
+		it.items = items
+		it.pos = 0
+		it.skip, it.done = advancePage(it.skip, len(items), total)
+		if len(items) == 0 {
+			return false
+		}
+	}
+	it.pos++
+	return true
+}
+
+// Item returns the subscriber the most recent call to Next advanced to.
+func (it *SubscribersIterator) Item() Subscriber {
+	return it.items[it.pos-1]
+}
+
+// Err returns the error, if any, that stopped iteration.
+func (it *SubscribersIterator) Err() error {
+	return it.err
+}
+
+// Close releases any resources held by the iterator. It is always safe to
+// call, and currently always returns nil; it exists so SubscribersIterator
+// can later move to cursor-based pagination without changing callers.
+func (it *SubscribersIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package mailgun
+
+import "testing"
+
+func TestAdvancePage(t *testing.T) {
+	cases := []struct {
+		name                string
+		skip, itemCount, total int
+		wantSkip            int
+		wantDone            bool
+	}{
+		{"more pages remain", 0, 100, 250, 100, false},
+		{"last page exactly exhausts total", 200, 50, 250, 250, true},
+		{"first page already covers total", 0, 10, 10, 10, true},
+		{"empty page terminates regardless of total", 50, 0, 250, 50, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotSkip, gotDone := advancePage(c.skip, c.itemCount, c.total)
+			if gotSkip != c.wantSkip || gotDone != c.wantDone {
+				t.Errorf("advancePage(%d, %d, %d) = (%d, %v), want (%d, %v)",
+					c.skip, c.itemCount, c.total, gotSkip, gotDone, c.wantSkip, c.wantDone)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package mailgun
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBatchRanges(t *testing.T) {
+	cases := []struct {
+		name    string
+		n, size int
+		want    [][2]int
+	}{
+		{"empty input", 0, 3, nil},
+		{"fewer than one batch", 2, 3, [][2]int{{0, 2}}},
+		{"exactly one batch", 3, 3, [][2]int{{0, 3}}},
+		{"one more than a batch", 4, 3, [][2]int{{0, 3}, {3, 4}}},
+		{"exactly two batches", 6, 3, [][2]int{{0, 3}, {3, 6}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := batchRanges(c.n, c.size)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("batchRanges(%d, %d) = %v, want %v", c.n, c.size, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSubscriberSubscribedJSONRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+		want SubscriptionFilter
+	}{
+		{"subscribed true", `{"address":"a@example.com","subscribed":true}`, FilterSubscribed},
+		{"subscribed false", `{"address":"a@example.com","subscribed":false}`, FilterUnsubscribed},
+		{"subscribed absent", `{"address":"a@example.com"}`, FilterAll},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var s Subscriber
+			if err := json.Unmarshal([]byte(c.json), &s); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if s.Subscribed != c.want {
+				t.Fatalf("Subscribed = %v, want %v", s.Subscribed, c.want)
+			}
+
+			b, err := json.Marshal(s)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var roundTripped Subscriber
+			if err := json.Unmarshal(b, &roundTripped); err != nil {
+				t.Fatalf("Unmarshal after Marshal: %v", err)
+			}
+			if roundTripped.Subscribed != c.want {
+				t.Fatalf("after round-trip Subscribed = %v, want %v", roundTripped.Subscribed, c.want)
+			}
+		})
+	}
+}
+
+func TestListAccessLevelJSONRoundTrip(t *testing.T) {
+	in := List{Address: "list@example.com", AccessLevel: AccessMembers}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out List
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.AccessLevel != AccessMembers {
+		t.Fatalf("AccessLevel = %v, want %v", out.AccessLevel, AccessMembers)
+	}
+}